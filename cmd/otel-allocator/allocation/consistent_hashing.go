@@ -2,6 +2,7 @@ package allocation
 
 import (
 	"fmt"
+	"math"
 	"net/url"
 	"sync"
 
@@ -13,16 +14,74 @@ import (
 	"github.com/open-telemetry/opentelemetry-operator/cmd/otel-allocator/diff"
 )
 
+// Allocator assigns scrape targets to collectors and notifies subscribers when an assignment
+// changes. consistentHashingAllocator is currently the only implementation.
+type Allocator interface {
+	// SetTargets reconciles the full set of scrape targets, keyed by TargetItem.Hash(), against
+	// what's currently assigned.
+	SetTargets(targets map[string]*TargetItem)
+	// SetCollectors reconciles the full set of available collectors, keyed by name, against
+	// what's currently known.
+	SetCollectors(collectors map[string]*Collector)
+	// SetCollectorWeight updates a collector's declared scrape-capacity weight.
+	SetCollectorWeight(name string, weight float64)
+	// TargetItems returns a shallow copy of the current target assignment.
+	TargetItems() map[string]*TargetItem
+	// Collectors returns a shallow copy of the currently known collectors.
+	Collectors() map[string]*Collector
+	// Subscribe registers a channel to receive TargetDiff notifications for a collector.
+	Subscribe(collectorName string) <-chan TargetDiff
+	// Unsubscribe removes and closes a channel previously returned by Subscribe.
+	Unsubscribe(collectorName string, ch <-chan TargetDiff)
+}
+
 var _ Allocator = &consistentHashingAllocator{}
 
 const consistentHashingStrategyName = "consistent-hashing"
 
+// defaultBaseVNodes is the number of virtual nodes a collector of average weight gets on the
+// ring; a collector's actual share is scaled by its weight relative to the mean weight.
+const defaultBaseVNodes = 100
+
+// defaultWeightEpsilon is how much a collector's weight has to change, relative to its last
+// registered weight, before its virtual nodes are recomputed. This keeps small, noisy weight
+// updates (e.g. from an EWMA over scrape_samples_scraped) from constantly re-registering the
+// collector and causing target churn.
+const defaultWeightEpsilon = 0.10
+
+const (
+	reasonCollectorAdded   = "collector_added"
+	reasonCollectorRemoved = "collector_removed"
+	reasonWeightChanged    = "weight_changed"
+)
+
+var TargetReassignments = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "opentelemetry_allocator_target_reassignments_total",
+	Help: "Number of targets moved to a different collector after a ring change, labeled by the reason for the move.",
+}, []string{"reason"})
+
+func init() {
+	prometheus.MustRegister(TargetReassignments)
+}
+
 type hasher struct{}
 
 func (h hasher) Sum64(data []byte) uint64 {
 	return xxhash.Sum64(data)
 }
 
+// virtualNode lets a single Collector occupy several positions on the consistent-hash ring, in
+// proportion to its weight, without the underlying consistent.Consistent ring needing to know
+// about per-member weights itself.
+type virtualNode struct {
+	collector string
+	index     int
+}
+
+func (v virtualNode) String() string {
+	return fmt.Sprintf("%s$%d", v.collector, v.index)
+}
+
 type consistentHashingAllocator struct {
 	// m protects consistentHasher, collectors and targetItems for concurrent use.
 	m sync.RWMutex
@@ -35,9 +94,45 @@ type consistentHashingAllocator struct {
 	// targetItems is a map from a target item's hash to the target items allocated state
 	targetItems map[string]*TargetItem
 
+	// subMtx protects subscribers and lastNotified for concurrent use.
+	subMtx sync.Mutex
+
+	// subscribers is a map from a Collector's name to the channels watching that collector's assignment.
+	subscribers map[string][]chan TargetDiff
+
+	// lastNotified holds, per collector, the last assignment snapshot that was pushed to its subscribers.
+	lastNotified map[string]map[string]*TargetItem
+
+	// weights is a map from a Collector's name to its declared scrape-capacity weight.
+	weights map[string]float64
+
+	// pendingWeights holds a weight set via SetCollectorWeight for a collector that isn't in
+	// c.collectors yet (SetCollectors hasn't reported it), so it isn't discarded: handleCollectors
+	// consults it when the collector is actually added.
+	pendingWeights map[string]float64
+
+	// vnodeCounts is a map from a Collector's name to the number of virtual nodes it currently
+	// has registered on consistentHasher.
+	vnodeCounts map[string]int
+
+	// vnodeOwner maps a registered virtual node's String() back to the Collector name it belongs to.
+	vnodeOwner map[string]string
+
+	// weightEpsilon is the minimum relative weight change that triggers re-registering a collector.
+	weightEpsilon float64
+
+	// baseVNodes is the virtual node count given to a collector of average weight.
+	baseVNodes int
+
 	log logr.Logger
 }
 
+// NewConsistentHashingAllocator constructs an Allocator that assigns targets to collectors by
+// consistent hashing, giving each collector a share of the ring proportional to its weight.
+func NewConsistentHashingAllocator(log logr.Logger) Allocator {
+	return newConsistentHashingAllocator(log)
+}
+
 func newConsistentHashingAllocator(log logr.Logger) Allocator {
 	config := consistent.Config{
 		PartitionCount:    1061,
@@ -50,10 +145,225 @@ func newConsistentHashingAllocator(log logr.Logger) Allocator {
 		consistentHasher: consistentHasher,
 		collectors:       make(map[string]*Collector),
 		targetItems:      make(map[string]*TargetItem),
+		subscribers:      make(map[string][]chan TargetDiff),
+		lastNotified:     make(map[string]map[string]*TargetItem),
+		weights:          make(map[string]float64),
+		pendingWeights:   make(map[string]float64),
+		vnodeCounts:      make(map[string]int),
+		vnodeOwner:       make(map[string]string),
+		weightEpsilon:    defaultWeightEpsilon,
+		baseVNodes:       defaultBaseVNodes,
 		log:              log,
 	}
 }
 
+// TargetDiff describes the targets that were added to or removed from a single collector's assignment.
+type TargetDiff struct {
+	Additions []*TargetItem
+	Removals  []*TargetItem
+}
+
+// Subscribe registers a channel to receive TargetDiff notifications whenever the given collector's
+// assignment changes. The returned channel is buffered so that a slow consumer does not block
+// allocation; callers must keep draining it and call Unsubscribe when they are done watching.
+func (c *consistentHashingAllocator) Subscribe(collectorName string) <-chan TargetDiff {
+	c.subMtx.Lock()
+	defer c.subMtx.Unlock()
+	ch := make(chan TargetDiff, 10)
+	c.subscribers[collectorName] = append(c.subscribers[collectorName], ch)
+	return ch
+}
+
+// Unsubscribe removes a previously registered channel from a collector's notification list and
+// closes it. It is a no-op if the channel is not currently registered.
+func (c *consistentHashingAllocator) Unsubscribe(collectorName string, ch <-chan TargetDiff) {
+	c.subMtx.Lock()
+	defer c.subMtx.Unlock()
+	subs := c.subscribers[collectorName]
+	for i, sub := range subs {
+		if sub == ch {
+			close(sub)
+			c.subscribers[collectorName] = append(subs[:i], subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// notifySubscribers recomputes the current per-collector assignment from c.targetItems, diffs it
+// against the last snapshot sent to that collector's subscribers, and pushes the delta. Callers
+// must hold c.m for at least reading when this is called.
+func (c *consistentHashingAllocator) notifySubscribers() {
+	current := make(map[string]map[string]*TargetItem, len(c.collectors))
+	for name := range c.collectors {
+		current[name] = make(map[string]*TargetItem)
+	}
+	for hash, item := range c.targetItems {
+		if _, ok := current[item.CollectorName]; !ok {
+			current[item.CollectorName] = make(map[string]*TargetItem)
+		}
+		current[item.CollectorName][hash] = item
+	}
+
+	c.subMtx.Lock()
+	defer c.subMtx.Unlock()
+	for name, items := range current {
+		subs := c.subscribers[name]
+		previous := c.lastNotified[name]
+		if len(subs) == 0 {
+			c.lastNotified[name] = items
+			continue
+		}
+		var tDiff TargetDiff
+		for hash, item := range items {
+			if _, ok := previous[hash]; !ok {
+				tDiff.Additions = append(tDiff.Additions, item)
+			}
+		}
+		for hash, item := range previous {
+			if _, ok := items[hash]; !ok {
+				tDiff.Removals = append(tDiff.Removals, item)
+			}
+		}
+		c.lastNotified[name] = items
+		if len(tDiff.Additions) == 0 && len(tDiff.Removals) == 0 {
+			continue
+		}
+		for _, sub := range subs {
+			select {
+			case sub <- tDiff:
+			default:
+				c.log.Info("dropping target diff notification, subscriber channel is full", "collector", name)
+			}
+		}
+	}
+}
+
+// locateCollector locates the ring owner for key and resolves it back to a Collector name,
+// translating away the virtualNode wrapping used to give weighted collectors extra ring share.
+func (c *consistentHashingAllocator) locateCollector(key []byte) string {
+	owner := c.consistentHasher.LocateKey(key)
+	if name, ok := c.vnodeOwner[owner.String()]; ok {
+		return name
+	}
+	return owner.String()
+}
+
+// meanWeight returns the mean declared weight across all known collectors, defaulting to 1 when
+// none have a weight set yet.
+func (c *consistentHashingAllocator) meanWeight() float64 {
+	if len(c.weights) == 0 {
+		return 1
+	}
+	var sum float64
+	for _, w := range c.weights {
+		sum += w
+	}
+	return sum / float64(len(c.weights))
+}
+
+// registerCollector (re-)registers a collector's virtual nodes so its share of the ring is
+// ceil(baseVNodes * weight / meanWeight). It is a no-op if the collector's vnode count would not
+// change, so callers can call it unconditionally after updating c.weights.
+func (c *consistentHashingAllocator) registerCollector(name string) {
+	weight := c.weights[name]
+	if weight <= 0 {
+		weight = 1
+	}
+	n := int(math.Ceil(float64(c.baseVNodes) * weight / c.meanWeight()))
+	if n < 1 {
+		n = 1
+	}
+	if existing, ok := c.vnodeCounts[name]; ok && existing == n {
+		return
+	}
+	c.unregisterVNodes(name)
+	for i := 0; i < n; i++ {
+		vn := virtualNode{collector: name, index: i}
+		c.consistentHasher.Add(vn)
+		c.vnodeOwner[vn.String()] = name
+	}
+	c.vnodeCounts[name] = n
+}
+
+// unregisterVNodes removes all virtual nodes currently registered for a collector.
+func (c *consistentHashingAllocator) unregisterVNodes(name string) {
+	for i := 0; i < c.vnodeCounts[name]; i++ {
+		vn := virtualNode{collector: name, index: i}
+		c.consistentHasher.Remove(vn.String())
+		delete(c.vnodeOwner, vn.String())
+	}
+	delete(c.vnodeCounts, name)
+}
+
+// SetCollectorWeight updates a collector's declared scrape-capacity weight. The weight is
+// expected to come either from the collector pod's targetallocator.opentelemetry.io/weight
+// annotation or from an EWMA over its observed scrape_samples_scraped. If the change is smaller
+// than weightEpsilon relative to the last registered weight it is ignored, to avoid ring churn
+// from noisy feedback; otherwise the collector is re-registered and only the targets whose owner
+// actually changed are moved.
+//
+// If the collector hasn't been reported by SetCollectors yet, the weight can't be registered on
+// the ring immediately - there would be no Collector for targets to land on - so it is held in
+// pendingWeights and applied by handleCollectors once the collector actually appears, rather than
+// being silently discarded.
+func (c *consistentHashingAllocator) SetCollectorWeight(name string, weight float64) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if weight <= 0 {
+		weight = 1
+	}
+
+	if _, ok := c.collectors[name]; !ok {
+		c.pendingWeights[name] = weight
+		return
+	}
+	previous := c.weights[name]
+	if previous == 0 {
+		previous = 1
+	}
+	if math.Abs(weight-previous)/previous < c.weightEpsilon {
+		return
+	}
+	c.weights[name] = weight
+	c.registerCollector(name)
+	c.reassignDrifted(reasonWeightChanged)
+	c.notifySubscribers()
+}
+
+// reassignDrifted re-locates every existing target against the current ring and moves only the
+// ones whose owner changed, instead of reassigning the whole targetItems map on every ring
+// change. A target's ring owner is always respected: after this returns, every target's
+// CollectorName equals what locateCollector would return for it.
+//
+// A moved target's *TargetItem is replaced rather than mutated in place: TargetItems() hands
+// out its pointers without copying them, so mutating item.CollectorName here would race with a
+// caller (e.g. GRPCServer.WatchTargets) reading that same pointer without holding c.m.
+func (c *consistentHashingAllocator) reassignDrifted(reason string) {
+	for hash, item := range c.targetItems {
+		newName := c.locateCollector([]byte(item.Hash()))
+		if newName == item.CollectorName {
+			continue
+		}
+		if oldCol, ok := c.collectors[item.CollectorName]; ok {
+			oldCol.NumTargets--
+			TargetsPerCollector.WithLabelValues(item.CollectorName, consistentHashingStrategyName).Set(float64(oldCol.NumTargets))
+		}
+		c.targetItems[hash] = &TargetItem{
+			JobName:       item.JobName,
+			Link:          item.Link,
+			TargetURL:     item.TargetURL,
+			Label:         item.Label,
+			CollectorName: newName,
+		}
+		if newCol, ok := c.collectors[newName]; ok {
+			newCol.NumTargets++
+			TargetsPerCollector.WithLabelValues(newName, consistentHashingStrategyName).Set(float64(newCol.NumTargets))
+		}
+		TargetReassignments.WithLabelValues(reason).Inc()
+	}
+}
+
 // addTargetToTargetItems assigns a target to the collector based on its hash and adds it to the allocator's targetItems
 // This method is called from within SetTargets and SetCollectors, which acquire the needed lock.
 // This is only called after the collectors are cleared or when a new target has been found in the tempTargetMap
@@ -64,17 +374,17 @@ func (c *consistentHashingAllocator) addTargetToTargetItems(target *TargetItem)
 		previousColName.NumTargets--
 		TargetsPerCollector.WithLabelValues(previousColName.String(), consistentHashingStrategyName).Set(float64(c.collectors[previousColName.String()].NumTargets))
 	}
-	colOwner := c.consistentHasher.LocateKey([]byte(target.Hash()))
+	colName := c.locateCollector([]byte(target.Hash()))
 	targetItem := &TargetItem{
 		JobName:       target.JobName,
 		Link:          LinkJSON{Link: fmt.Sprintf("/jobs/%s/targets", url.QueryEscape(target.JobName))},
 		TargetURL:     target.TargetURL,
 		Label:         target.Label,
-		CollectorName: colOwner.String(),
+		CollectorName: colName,
 	}
 	c.targetItems[targetItem.Hash()] = targetItem
-	c.collectors[colOwner.String()].NumTargets++
-	TargetsPerCollector.WithLabelValues(colOwner.String(), consistentHashingStrategyName).Set(float64(c.collectors[colOwner.String()].NumTargets))
+	c.collectors[colName].NumTargets++
+	TargetsPerCollector.WithLabelValues(colName, consistentHashingStrategyName).Set(float64(c.collectors[colName].NumTargets))
 }
 
 // handleTargets receives the new and removed targets and reconciles the current state.
@@ -105,24 +415,35 @@ func (c *consistentHashingAllocator) handleTargets(diff diff.Changes[*TargetItem
 }
 
 // handleCollectors receives the new and removed collectors and reconciles the current state.
-// Any removals are removed from the allocator's collectors. New collectors are added to the allocator's collector map
-// Finally, update all targets' collectors to match the consistent hashing.
+// Any removals are removed from the allocator's collectors. New collectors are added to the
+// allocator's collector map. Finally, only the targets whose ring owner actually changed are
+// moved to their new collector; the rest are left untouched so that a collector being added or
+// removed doesn't reshuffle every target in the allocator.
 func (c *consistentHashingAllocator) handleCollectors(diff diff.Changes[*Collector]) {
 	// Clear removed collectors
 	for _, k := range diff.Removals() {
 		delete(c.collectors, k.Name)
-		c.consistentHasher.Remove(k.Name)
+		delete(c.weights, k.Name)
+		c.unregisterVNodes(k.Name)
 		TargetsPerCollector.WithLabelValues(k.Name, consistentHashingStrategyName).Set(0)
 	}
 	// Insert the new collectors
 	for _, i := range diff.Additions() {
 		c.collectors[i.Name] = NewCollector(i.Name)
-		c.consistentHasher.Add(c.collectors[i.Name])
+		if pending, ok := c.pendingWeights[i.Name]; ok {
+			c.weights[i.Name] = pending
+			delete(c.pendingWeights, i.Name)
+		} else if _, ok := c.weights[i.Name]; !ok {
+			c.weights[i.Name] = 1
+		}
+		c.registerCollector(i.Name)
 	}
 
-	// Re-Allocate all targets
-	for _, item := range c.targetItems {
-		c.addTargetToTargetItems(item)
+	if len(diff.Removals()) != 0 {
+		c.reassignDrifted(reasonCollectorRemoved)
+	}
+	if len(diff.Additions()) != 0 {
+		c.reassignDrifted(reasonCollectorAdded)
 	}
 }
 
@@ -145,6 +466,7 @@ func (c *consistentHashingAllocator) SetTargets(targets map[string]*TargetItem)
 	// If there are any additions or removals
 	if len(targetsDiff.Additions()) != 0 || len(targetsDiff.Removals()) != 0 {
 		c.handleTargets(targetsDiff)
+		c.notifySubscribers()
 	}
 	return
 }
@@ -169,6 +491,7 @@ func (c *consistentHashingAllocator) SetCollectors(collectors map[string]*Collec
 	collectorsDiff := diff.Maps(c.collectors, collectors)
 	if len(collectorsDiff.Additions()) != 0 || len(collectorsDiff.Removals()) != 0 {
 		c.handleCollectors(collectorsDiff)
+		c.notifySubscribers()
 	}
 	return
 }
@@ -0,0 +1,65 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocation
+
+import (
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReassignDriftedReplacesRatherThanMutatesTargetItem guards against a data race: TargetItems()
+// hands out live *TargetItem pointers without copying them, so reassignDrifted must build a new
+// *TargetItem for a moved target instead of mutating the one callers may already be holding.
+func TestReassignDriftedReplacesRatherThanMutatesTargetItem(t *testing.T) {
+	c := newConsistentHashingAllocator(logr.Discard()).(*consistentHashingAllocator)
+	c.collectors["collector-1"] = NewCollector("collector-1")
+	c.collectors["collector-2"] = NewCollector("collector-2")
+
+	target := &TargetItem{JobName: "example", TargetURL: []string{"localhost:9090"}, CollectorName: "collector-1"}
+	hash := target.Hash()
+	c.targetItems[hash] = target
+
+	staleRef := c.targetItems[hash]
+	require.Equal(t, "collector-1", staleRef.CollectorName)
+
+	// Force a drift: remove collector-1's vnodes so every key now resolves to collector-2,
+	// disagreeing with the target's recorded owner.
+	c.unregisterVNodes("collector-1")
+	c.registerCollector("collector-2")
+
+	c.reassignDrifted(reasonWeightChanged)
+
+	assert.Equal(t, "collector-1", staleRef.CollectorName, "a pointer obtained before the reassignment must not be mutated")
+	assert.Equal(t, "collector-2", c.targetItems[hash].CollectorName, "the map entry must reflect the new owner")
+	assert.NotSame(t, staleRef, c.targetItems[hash], "reassignment must replace the TargetItem, not mutate it in place")
+}
+
+// TestSetCollectorWeightAppliesOncePending guards against silently discarding a weight set
+// before its collector has been reported by SetCollectors: a caller feeding weights from startup
+// flags or a pod-annotation watcher can easily race ahead of collector discovery.
+func TestSetCollectorWeightAppliesOncePending(t *testing.T) {
+	c := newConsistentHashingAllocator(logr.Discard()).(*consistentHashingAllocator)
+
+	c.SetCollectorWeight("collector-1", 42)
+	assert.NotContains(t, c.weights, "collector-1", "the weight must not be registered before the collector exists")
+
+	c.SetCollectors(map[string]*Collector{"collector-1": NewCollector("collector-1")})
+
+	assert.Equal(t, 42.0, c.weights["collector-1"], "a weight set before SetCollectors saw the collector must be applied once it's added")
+	assert.NotContains(t, c.pendingWeights, "collector-1")
+}
@@ -0,0 +1,65 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocation
+
+import "sync"
+
+// defaultEWMAAlpha weights the most recent sample against the running average. Lower values
+// smooth out noisier, slower-moving signals; this is deliberately small since a single scrape's
+// sample count can vary a lot run to run without the collector's real capacity having changed.
+const defaultEWMAAlpha = 0.2
+
+// weightSetter is the subset of Allocator that ScrapeSampleWeightTracker needs.
+type weightSetter interface {
+	SetCollectorWeight(name string, weight float64)
+}
+
+// ScrapeSampleWeightTracker derives a collector's consistent-hashing weight from an exponentially
+// weighted moving average of its self-reported scrape_samples_scraped value, so that a real,
+// sustained difference in scrape load shifts ring share, while a single noisy scrape doesn't.
+// SetCollectorWeight's own weightEpsilon guard additionally absorbs small EWMA movements.
+type ScrapeSampleWeightTracker struct {
+	alloc weightSetter
+	alpha float64
+
+	mtx  sync.Mutex
+	ewma map[string]float64
+}
+
+// NewScrapeSampleWeightTracker returns a tracker that calls alloc.SetCollectorWeight whenever a
+// collector's EWMA changes.
+func NewScrapeSampleWeightTracker(alloc weightSetter) *ScrapeSampleWeightTracker {
+	return &ScrapeSampleWeightTracker{
+		alloc: alloc,
+		alpha: defaultEWMAAlpha,
+		ewma:  make(map[string]float64),
+	}
+}
+
+// Observe records a new scrape_samples_scraped sample for collectorName and updates its declared
+// weight to the resulting EWMA.
+func (t *ScrapeSampleWeightTracker) Observe(collectorName string, scrapeSamplesScraped float64) {
+	t.mtx.Lock()
+	next, ok := t.ewma[collectorName]
+	if !ok {
+		next = scrapeSamplesScraped
+	} else {
+		next = t.alpha*scrapeSamplesScraped + (1-t.alpha)*next
+	}
+	t.ewma[collectorName] = next
+	t.mtx.Unlock()
+
+	t.alloc.SetCollectorWeight(collectorName, next)
+}
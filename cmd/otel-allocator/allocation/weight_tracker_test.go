@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeWeightSetter struct {
+	weights map[string]float64
+}
+
+func (f *fakeWeightSetter) SetCollectorWeight(name string, weight float64) {
+	f.weights[name] = weight
+}
+
+func TestScrapeSampleWeightTrackerSmoothsSuccessiveSamples(t *testing.T) {
+	setter := &fakeWeightSetter{weights: map[string]float64{}}
+	tracker := NewScrapeSampleWeightTracker(setter)
+
+	tracker.Observe("collector-1", 100)
+	assert.Equal(t, 100.0, setter.weights["collector-1"])
+
+	tracker.Observe("collector-1", 200)
+	assert.Equal(t, 0.2*200+0.8*100, setter.weights["collector-1"])
+}
+
+func TestScrapeSampleWeightTrackerTracksCollectorsIndependently(t *testing.T) {
+	setter := &fakeWeightSetter{weights: map[string]float64{}}
+	tracker := NewScrapeSampleWeightTracker(setter)
+
+	tracker.Observe("collector-1", 100)
+	tracker.Observe("collector-2", 10)
+
+	assert.Equal(t, 100.0, setter.weights["collector-1"])
+	assert.Equal(t, 10.0, setter.weights["collector-2"])
+}
@@ -0,0 +1,463 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filesd implements a file-based target discovery provider for the
+// allocator. It watches a set of files or directories for Prometheus-style
+// scrape configs and turns them into allocation.TargetItem values that can
+// be merged with Prometheus service-discovery targets before being handed
+// to an Allocator's SetTargets.
+package filesd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v2"
+
+	"github.com/open-telemetry/opentelemetry-operator/cmd/otel-allocator/allocation"
+)
+
+// defaultDebounce coalesces bursts of filesystem events (e.g. editors that
+// write a file in several small operations) into a single re-scan.
+const defaultDebounce = 200 * time.Millisecond
+
+var parseFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "otelcol_allocator_filesd_parse_failures_total",
+	Help: "Number of file-based scrape configs that failed to parse and were skipped.",
+}, []string{"file"})
+
+func init() {
+	prometheus.MustRegister(parseFailures)
+}
+
+// scrapeConfig is the subset of a Prometheus scrape_config that the file
+// discovery provider understands: inline static_configs and *_sd_config
+// snippets that other discovery mechanisms already know how to expand.
+type scrapeConfig struct {
+	JobName       string                   `yaml:"job_name"`
+	StaticConfigs []staticConfig           `yaml:"static_configs"`
+	SDConfigs     map[string][]interface{} `yaml:",inline"`
+}
+
+type staticConfig struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+// Provider watches a list of paths for scrape config files and emits the
+// combined set of targets they describe whenever the set changes.
+type Provider struct {
+	log      logr.Logger
+	paths    []string
+	debounce time.Duration
+
+	mtx    sync.Mutex
+	byFile map[string]map[string]*allocation.TargetItem // absolute path -> targets last parsed from it
+
+	// watching is a map from one of the configured paths to the resolved target fsnotify is
+	// currently watching on its behalf. It lets refreshWatches notice when a symlink (e.g. a
+	// Kubernetes ConfigMap's "..data" link) has been atomically repointed at a new target, since
+	// fsnotify itself keeps watching the old, now-deleted inode rather than following the link.
+	watching map[string]string
+
+	// fileResolved is the same idea as watching, but for every individual file rescan has ever
+	// been called on - including files discovered inside a watched directory, not just the
+	// top-level configured paths. This matters because a ConfigMap mount's per-key symlinks
+	// ("key -> ..data/key") never themselves change; only the directory's own "..data" link is
+	// repointed. fsnotify never reports an event on "key" in that case, so refreshWatches must
+	// re-resolve every known file here too, not just p.paths, to notice the drift at all.
+	fileResolved map[string]string
+
+	watcher *fsnotify.Watcher
+	targets chan map[string]*allocation.TargetItem
+}
+
+// NewProvider creates a Provider watching the given files and/or
+// directories. Directories are watched non-recursively; every regular file
+// under them that exists at startup (and every file later created in them)
+// is treated as a scrape config.
+func NewProvider(log logr.Logger, paths []string, debounce time.Duration) (*Provider, error) {
+	if debounce <= 0 {
+		debounce = defaultDebounce
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fsnotify watcher: %w", err)
+	}
+	p := &Provider{
+		log:          log.WithName("filesd"),
+		paths:        paths,
+		debounce:     debounce,
+		byFile:       make(map[string]map[string]*allocation.TargetItem),
+		watching:     make(map[string]string),
+		fileResolved: make(map[string]string),
+		watcher:      watcher,
+		targets:      make(chan map[string]*allocation.TargetItem, 1),
+	}
+	for _, path := range paths {
+		resolved, err := resolvePath(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		if err := watcher.Add(resolved); err != nil {
+			return nil, fmt.Errorf("failed to watch %s: %w", resolved, err)
+		}
+		p.watching[path] = resolved
+	}
+	return p, nil
+}
+
+// Allocator is the subset of allocation.Allocator that Sync needs in order to merge file-sourced
+// targets with whatever targets (e.g. from Prometheus SD) are already set on the allocator.
+type Allocator interface {
+	TargetItems() map[string]*allocation.TargetItem
+	SetTargets(targets map[string]*allocation.TargetItem)
+}
+
+// Sync runs the provider and, for every batch of file-sourced targets it produces, merges them
+// with the allocator's current non-file-sourced targets and calls SetTargets with the result.
+// This is the allocator-side integration point: a caller wires a Provider to a running allocator
+// by starting Sync in its own goroutine during startup, alongside whatever feeds the allocator
+// from Prometheus service discovery.
+func (p *Provider) Sync(ctx context.Context, alloc Allocator) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var lastFileHashes map[string]struct{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case fileTargets, ok := <-p.targets:
+				if !ok {
+					return
+				}
+				merged, newHashes := mergeFileTargets(alloc.TargetItems(), lastFileHashes, fileTargets)
+				lastFileHashes = newHashes
+				alloc.SetTargets(merged)
+			}
+		}
+	}()
+
+	err := p.Run(ctx)
+	<-done
+	return err
+}
+
+// mergeFileTargets combines a new file-sourced batch with an allocator's current target set,
+// dropping whatever the previous file-sourced batch contributed (tracked by lastFileHashes) so
+// that a target removed from disk doesn't linger. It returns the merged map and the set of
+// hashes the new batch contributed, to be passed back in as lastFileHashes next call.
+func mergeFileTargets(current map[string]*allocation.TargetItem, lastFileHashes map[string]struct{}, fileTargets map[string]*allocation.TargetItem) (map[string]*allocation.TargetItem, map[string]struct{}) {
+	merged := make(map[string]*allocation.TargetItem, len(current)+len(fileTargets))
+	for hash, item := range current {
+		if _, wasFileSourced := lastFileHashes[hash]; wasFileSourced {
+			continue // superseded below by the new file-sourced batch
+		}
+		merged[hash] = item
+	}
+	newHashes := make(map[string]struct{}, len(fileTargets))
+	for hash, item := range fileTargets {
+		merged[hash] = item
+		newHashes[hash] = struct{}{}
+	}
+	return merged, newHashes
+}
+
+// Targets returns a channel of the combined, de-duplicated set of targets
+// found across all watched files. A new map is sent every time the set
+// changes; the allocator is expected to merge it with targets from other
+// sources before calling SetTargets.
+func (p *Provider) Targets() <-chan map[string]*allocation.TargetItem {
+	return p.targets
+}
+
+// Run performs the initial scan and then watches for filesystem events
+// until the context is cancelled.
+func (p *Provider) Run(ctx context.Context) error {
+	defer p.watcher.Close()
+
+	if err := p.initialScan(); err != nil {
+		return err
+	}
+	p.emit()
+
+	var (
+		debounceTimer *time.Timer
+		pending       = make(map[string]struct{})
+	)
+	flush := func() {
+		// A rotation may have repointed a watched symlink at a brand new inode; pick that up
+		// before rescanning so we read (and keep watching) the new target, not the old one.
+		p.refreshWatches()
+		for path := range pending {
+			p.rescan(path)
+		}
+		pending = make(map[string]struct{})
+		p.emit()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			if debounceTimer != nil {
+				debounceTimer.Stop()
+			}
+			return ctx.Err()
+		case event, ok := <-p.watcher.Events:
+			if !ok {
+				return nil
+			}
+			// Atomic-rename writers (vim, k8s configmap mounts) show up as a
+			// REMOVE/RENAME of the old file followed by a CREATE of the new
+			// one; treat CREATE the same as WRITE so it triggers a re-scan.
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove|fsnotify.Rename) == 0 {
+				continue
+			}
+			pending[event.Name] = struct{}{}
+			if debounceTimer == nil {
+				debounceTimer = time.AfterFunc(p.debounce, flush)
+			} else {
+				debounceTimer.Reset(p.debounce)
+			}
+		case err, ok := <-p.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			p.log.Error(err, "fsnotify watcher error")
+		}
+	}
+}
+
+// refreshWatches re-resolves every top-level configured path and every individual file rescan
+// has seen, and for any whose symlink resolution has changed since last observed (as happens when
+// a Kubernetes ConfigMap mount swaps its "..data" link), drops the old target's cached
+// contribution and rescans the new target so it isn't silently dropped.
+//
+// The two loops below aren't redundant: the top-level loop also owns the fsnotify watch itself
+// (adding a watch on the new target, removing it from the old one), which only applies to the
+// directories and files actually passed to NewProvider. The per-file loop exists because a
+// ConfigMap mount's per-key symlinks ("key -> ..data/key") are never themselves modified by a
+// rotation - only the directory's own "..data" link moves - so fsnotify never reports an event
+// naming "key", and the top-level loop alone would never revisit it.
+func (p *Provider) refreshWatches() {
+	for _, path := range p.paths {
+		newResolved, err := resolvePath(path)
+		if err != nil {
+			continue
+		}
+		oldResolved := p.watching[path]
+		if newResolved == oldResolved {
+			continue
+		}
+		if oldResolved != "" {
+			if err := p.watcher.Remove(oldResolved); err != nil {
+				p.log.V(1).Info("failed to remove stale watch, it may already be gone", "path", oldResolved, "error", err)
+			}
+		}
+		if err := p.watcher.Add(newResolved); err != nil {
+			p.log.Error(err, "failed to watch rotated target, will retry on the next event", "path", path, "target", newResolved)
+			continue
+		}
+		p.log.Info("re-watching rotated target", "path", path, "previous", oldResolved, "current", newResolved)
+		p.watching[path] = newResolved
+
+		p.mtx.Lock()
+		delete(p.byFile, oldResolved)
+		delete(p.fileResolved, oldResolved)
+		p.mtx.Unlock()
+		p.rescan(newResolved)
+	}
+
+	p.mtx.Lock()
+	known := make(map[string]string, len(p.fileResolved))
+	for path, resolved := range p.fileResolved {
+		known[path] = resolved
+	}
+	p.mtx.Unlock()
+
+	for path, oldResolved := range known {
+		newResolved, err := resolvePath(path)
+		if err != nil || newResolved == oldResolved {
+			continue
+		}
+		p.log.Info("re-resolved rotated file, picking up new target", "path", path, "previous", oldResolved, "current", newResolved)
+
+		p.mtx.Lock()
+		delete(p.byFile, oldResolved)
+		p.mtx.Unlock()
+		// Rescan by the original (unresolved) path, not newResolved: rescan records
+		// fileResolved[path] = resolved, so passing the original path here is what
+		// actually updates the entry we just detected drift on.
+		p.rescan(path)
+	}
+}
+
+// initialScan populates byFile for every watched path so that the first
+// emitted batch already contains everything on disk, instead of reshuffling
+// target by target as individual files are picked up.
+func (p *Provider) initialScan() error {
+	for _, path := range p.paths {
+		resolved, err := resolvePath(path)
+		if err != nil {
+			return err
+		}
+		info, err := os.Stat(resolved)
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", resolved, err)
+		}
+		if !info.IsDir() {
+			p.rescan(resolved)
+			continue
+		}
+		entries, err := os.ReadDir(resolved)
+		if err != nil {
+			return fmt.Errorf("failed to read directory %s: %w", resolved, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			p.rescan(filepath.Join(resolved, entry.Name()))
+		}
+	}
+	return nil
+}
+
+// rescan re-reads a single file and updates the per-file cache. A missing
+// file clears that file's contribution; a file that fails to parse keeps
+// the last-known-good contents and increments parseFailures.
+func (p *Provider) rescan(path string) {
+	resolved, err := resolvePath(path)
+	if err != nil {
+		resolved = path
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.fileResolved == nil {
+		p.fileResolved = make(map[string]string)
+	}
+	p.fileResolved[path] = resolved
+
+	if _, err := os.Stat(resolved); os.IsNotExist(err) {
+		delete(p.byFile, resolved)
+		return
+	}
+
+	items, err := parseFile(resolved)
+	if err != nil {
+		p.log.Error(err, "failed to parse file-based scrape config, keeping last-known-good copy", "file", resolved)
+		parseFailures.WithLabelValues(resolved).Inc()
+		return
+	}
+	p.byFile[resolved] = items
+}
+
+// emit pushes the current combined view of byFile to the Targets channel,
+// dropping any previously queued-but-unconsumed batch.
+func (p *Provider) emit() {
+	p.mtx.Lock()
+	combined := make(map[string]*allocation.TargetItem)
+	for _, items := range p.byFile {
+		for k, v := range items {
+			combined[k] = v
+		}
+	}
+	p.mtx.Unlock()
+
+	select {
+	case <-p.targets:
+	default:
+	}
+	p.targets <- combined
+}
+
+// parseFile reads a scrape-config-style YAML/JSON file and converts any
+// static_configs entries it finds into TargetItems; *_sd_config entries are
+// accepted without error but produce no targets. Both
+// YAML and JSON are handled by the same decoder since JSON is a subset of
+// YAML.
+func parseFile(path string) (map[string]*allocation.TargetItem, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var configs []scrapeConfig
+	if err := yaml.Unmarshal(raw, &configs); err != nil {
+		// Some file_sd-style files are just a single static_configs document
+		// rather than a list of scrape_configs; fall back to that shape.
+		var single scrapeConfig
+		if err2 := yaml.Unmarshal(raw, &single); err2 != nil {
+			return nil, fmt.Errorf("failed to parse %s as a scrape config: %w", path, err)
+		}
+		configs = []scrapeConfig{single}
+	}
+
+	items := make(map[string]*allocation.TargetItem)
+	for _, cfg := range configs {
+		for _, sc := range cfg.StaticConfigs {
+			for _, target := range sc.Targets {
+				item := &allocation.TargetItem{
+					JobName:   cfg.JobName,
+					TargetURL: []string{target},
+					Label:     labelSetFrom(sc.Labels),
+				}
+				items[item.Hash()] = item
+			}
+		}
+		// *_sd_config snippets (consul_sd_configs, kubernetes_sd_configs, ...)
+		// describe dynamic discovery rather than concrete targets; they are
+		// accepted here so the config is not rejected outright, but expanding
+		// them is left to the corresponding upstream discovery mechanism.
+	}
+	return items, nil
+}
+
+// labelSetFrom converts a plain string map, as found in a static_config's
+// labels block, into a model.LabelSet.
+func labelSetFrom(labels map[string]string) model.LabelSet {
+	set := make(model.LabelSet, len(labels))
+	for k, v := range labels {
+		set[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return set
+}
+
+// resolvePath re-resolves symlinks on every call so that a watched path
+// that points through a symlinked directory (as with a Kubernetes
+// ConfigMap volume mount) keeps following the link after it is swapped.
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		// The path may not exist yet (e.g. it will be created by a later
+		// event); fall back to the absolute, non-resolved form.
+		return abs, nil
+	}
+	return resolved, nil
+}
@@ -0,0 +1,266 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filesd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/open-telemetry/opentelemetry-operator/cmd/otel-allocator/allocation"
+)
+
+func TestParseFileStaticConfigs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scrape.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+- job_name: 'example'
+  static_configs:
+    - targets: ['localhost:9090']
+      labels:
+        env: 'prod'
+`), 0o644))
+
+	items, err := parseFile(path)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+	for _, item := range items {
+		assert.Equal(t, "example", item.JobName)
+		assert.Equal(t, []string{"localhost:9090"}, item.TargetURL)
+	}
+}
+
+func TestParseFileInvalidKeepsNoResult(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scrape.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("not: [valid"), 0o644))
+
+	_, err := parseFile(path)
+	assert.Error(t, err)
+}
+
+func TestRescanRemovesDeletedFileContribution(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scrape.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+- job_name: 'example'
+  static_configs:
+    - targets: ['localhost:9090']
+`), 0o644))
+
+	p := &Provider{byFile: make(map[string]map[string]*allocation.TargetItem)}
+	p.rescan(path)
+	assert.Len(t, p.byFile[path], 1)
+
+	require.NoError(t, os.Remove(path))
+	p.rescan(path)
+	_, ok := p.byFile[path]
+	assert.False(t, ok)
+}
+
+func TestRefreshWatchesFollowsRotatedSymlink(t *testing.T) {
+	dir := t.TempDir()
+
+	oldTarget := filepath.Join(dir, "v1.yaml")
+	require.NoError(t, os.WriteFile(oldTarget, []byte(`
+- job_name: 'v1'
+  static_configs:
+    - targets: ['localhost:1111']
+`), 0o644))
+
+	link := filepath.Join(dir, "scrape.yaml")
+	require.NoError(t, os.Symlink(oldTarget, link))
+
+	p, err := NewProvider(logr.Discard(), []string{link}, 0)
+	require.NoError(t, err)
+	defer p.watcher.Close()
+
+	resolvedOld, err := resolvePath(oldTarget)
+	require.NoError(t, err)
+	p.rescan(resolvedOld)
+	require.Len(t, p.byFile[resolvedOld], 1)
+
+	// Simulate an atomic ConfigMap-style rotation: point the symlink at a new target file.
+	newTarget := filepath.Join(dir, "v2.yaml")
+	require.NoError(t, os.WriteFile(newTarget, []byte(`
+- job_name: 'v2'
+  static_configs:
+    - targets: ['localhost:2222']
+`), 0o644))
+	require.NoError(t, os.Remove(link))
+	require.NoError(t, os.Symlink(newTarget, link))
+
+	p.refreshWatches()
+
+	resolvedNew, err := resolvePath(newTarget)
+	require.NoError(t, err)
+	assert.Equal(t, resolvedNew, p.watching[link])
+	assert.NotContains(t, p.byFile, resolvedOld)
+	require.Len(t, p.byFile[resolvedNew], 1)
+	for _, item := range p.byFile[resolvedNew] {
+		assert.Equal(t, "v2", item.JobName)
+	}
+}
+
+// TestRefreshWatchesFollowsRotatedSymlinkInsideWatchedDirectory covers the case
+// TestRefreshWatchesFollowsRotatedSymlink doesn't: a directory passed to NewProvider, where the
+// directory itself never changes (as with a Kubernetes ConfigMap mount point) but a per-key
+// symlink nested inside it is rotated via an indirection that lives outside the watched
+// directory (as with a ConfigMap's "..data" link). The top-level refreshWatches loop is a no-op
+// here since the watched directory's own resolution never changes; only the per-file loop over
+// every rescanned file catches the drift.
+func TestRefreshWatchesFollowsRotatedSymlinkInsideWatchedDirectory(t *testing.T) {
+	root := t.TempDir()
+
+	watchedDir := filepath.Join(root, "config")
+	require.NoError(t, os.Mkdir(watchedDir, 0o755))
+
+	dataV1 := filepath.Join(root, "data-v1")
+	require.NoError(t, os.Mkdir(dataV1, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dataV1, "key1"), []byte(`
+- job_name: 'v1'
+  static_configs:
+    - targets: ['localhost:1111']
+`), 0o644))
+
+	current := filepath.Join(root, "current")
+	require.NoError(t, os.Symlink(dataV1, current))
+	require.NoError(t, os.Symlink(filepath.Join(current, "key1"), filepath.Join(watchedDir, "key1")))
+
+	p, err := NewProvider(logr.Discard(), []string{watchedDir}, 0)
+	require.NoError(t, err)
+	defer p.watcher.Close()
+
+	require.NoError(t, p.initialScan())
+
+	resolvedOld, err := resolvePath(filepath.Join(watchedDir, "key1"))
+	require.NoError(t, err)
+	require.Len(t, p.byFile[resolvedOld], 1)
+
+	// Simulate an atomic ConfigMap-style rotation: repoint "current" at a new data directory.
+	// The "key1" symlink inside the watched directory is untouched - only "current", which lives
+	// outside it, moves.
+	dataV2 := filepath.Join(root, "data-v2")
+	require.NoError(t, os.Mkdir(dataV2, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dataV2, "key1"), []byte(`
+- job_name: 'v2'
+  static_configs:
+    - targets: ['localhost:2222']
+`), 0o644))
+	require.NoError(t, os.Remove(current))
+	require.NoError(t, os.Symlink(dataV2, current))
+
+	p.refreshWatches()
+
+	resolvedNew, err := resolvePath(filepath.Join(watchedDir, "key1"))
+	require.NoError(t, err)
+	assert.NotEqual(t, resolvedOld, resolvedNew)
+	assert.NotContains(t, p.byFile, resolvedOld)
+	require.Len(t, p.byFile[resolvedNew], 1)
+	for _, item := range p.byFile[resolvedNew] {
+		assert.Equal(t, "v2", item.JobName)
+	}
+}
+
+func TestMergeFileTargetsReplacesOnlyPreviousFileContribution(t *testing.T) {
+	sdItem := &allocation.TargetItem{JobName: "sd", TargetURL: []string{"sd:1"}}
+	staleFileItem := &allocation.TargetItem{JobName: "file-old", TargetURL: []string{"file-old:1"}}
+	current := map[string]*allocation.TargetItem{
+		sdItem.Hash():        sdItem,
+		staleFileItem.Hash(): staleFileItem,
+	}
+	lastFileHashes := map[string]struct{}{staleFileItem.Hash(): {}}
+
+	newFileItem := &allocation.TargetItem{JobName: "file-new", TargetURL: []string{"file-new:1"}}
+	fileTargets := map[string]*allocation.TargetItem{newFileItem.Hash(): newFileItem}
+
+	merged, newHashes := mergeFileTargets(current, lastFileHashes, fileTargets)
+
+	assert.Len(t, merged, 2)
+	assert.Contains(t, merged, sdItem.Hash())
+	assert.Contains(t, merged, newFileItem.Hash())
+	assert.NotContains(t, merged, staleFileItem.Hash())
+	assert.Equal(t, map[string]struct{}{newFileItem.Hash(): {}}, newHashes)
+}
+
+// fakeAllocator is a minimal filesd.Allocator used to exercise Sync without a real
+// consistentHashingAllocator, which isn't constructible from outside the allocation package.
+type fakeAllocator struct {
+	mtx     sync.Mutex
+	items   map[string]*allocation.TargetItem
+	setCall chan map[string]*allocation.TargetItem
+}
+
+func newFakeAllocator() *fakeAllocator {
+	return &fakeAllocator{items: map[string]*allocation.TargetItem{}, setCall: make(chan map[string]*allocation.TargetItem, 10)}
+}
+
+func (f *fakeAllocator) TargetItems() map[string]*allocation.TargetItem {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	out := make(map[string]*allocation.TargetItem, len(f.items))
+	for k, v := range f.items {
+		out[k] = v
+	}
+	return out
+}
+
+func (f *fakeAllocator) SetTargets(targets map[string]*allocation.TargetItem) {
+	f.mtx.Lock()
+	f.items = targets
+	f.mtx.Unlock()
+	f.setCall <- targets
+}
+
+func TestSyncMergesFileTargetsIntoAllocator(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scrape.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+- job_name: 'example'
+  static_configs:
+    - targets: ['localhost:9090']
+`), 0o644))
+
+	p, err := NewProvider(logr.Discard(), []string{dir}, 10*time.Millisecond)
+	require.NoError(t, err)
+
+	alloc := newFakeAllocator()
+	sdItem := &allocation.TargetItem{JobName: "sd", TargetURL: []string{"sd:1"}}
+	alloc.items[sdItem.Hash()] = sdItem
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- p.Sync(ctx, alloc) }()
+
+	select {
+	case targets := <-alloc.setCall:
+		assert.Len(t, targets, 2)
+		assert.Contains(t, targets, sdItem.Hash())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for Sync to call SetTargets")
+	}
+
+	cancel()
+	<-done
+}
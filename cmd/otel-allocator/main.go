@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command otel-allocator assigns Prometheus scrape targets to a fleet of collectors.
+//
+// This entry point only wires up the pieces that exist in this checkout: file-based target
+// discovery (cmd/otel-allocator/filesd) and the gRPC target-assignment transport
+// (cmd/otel-allocator/server). It does not start an HTTP server or watch collector pods, since
+// neither of those components is present here.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	stdlog "log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/go-logr/logr/stdr"
+
+	"github.com/open-telemetry/opentelemetry-operator/cmd/otel-allocator/allocation"
+	"github.com/open-telemetry/opentelemetry-operator/cmd/otel-allocator/filesd"
+	"github.com/open-telemetry/opentelemetry-operator/cmd/otel-allocator/server"
+)
+
+// repeatedFlag collects the values of a flag that may be passed more than once.
+type repeatedFlag []string
+
+func (r *repeatedFlag) String() string { return strings.Join(*r, ",") }
+
+func (r *repeatedFlag) Set(v string) error {
+	*r = append(*r, v)
+	return nil
+}
+
+// parseCollectorWeight splits a "name=weight" flag value into its collector name and weight.
+func parseCollectorWeight(kv string) (string, float64, error) {
+	name, weightStr, ok := strings.Cut(kv, "=")
+	if !ok || name == "" {
+		return "", 0, fmt.Errorf("expected name=weight, got %q", kv)
+	}
+	weight, err := strconv.ParseFloat(weightStr, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid weight in %q: %w", kv, err)
+	}
+	return name, weight, nil
+}
+
+func main() {
+	var configFiles repeatedFlag
+	flag.Var(&configFiles, "config-file", "path to a file-based scrape config, or a directory of them; may be repeated")
+
+	grpcListenAddr := flag.String("grpc-listen-addr", "", "address to serve the TargetAllocator gRPC service on; the service is disabled if empty")
+
+	var collectorWeights repeatedFlag
+	flag.Var(&collectorWeights, "collector-weight", "name=weight setting a collector's initial consistent-hashing weight; may be repeated")
+	flag.Parse()
+
+	log := stdr.New(stdlog.New(os.Stderr, "", stdlog.LstdFlags))
+
+	alloc := allocation.NewConsistentHashingAllocator(log)
+
+	// There is no collector-watching component in this checkout to call SetCollectors, so these
+	// weights take effect only once something else registers a collector under the same name.
+	for _, kv := range collectorWeights {
+		name, weight, err := parseCollectorWeight(kv)
+		if err != nil {
+			log.Error(err, "invalid -collector-weight", "value", kv)
+			os.Exit(1)
+		}
+		alloc.SetCollectorWeight(name, weight)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if len(configFiles) > 0 {
+		provider, err := filesd.NewProvider(log, configFiles, 0)
+		if err != nil {
+			log.Error(err, "failed to start file-based target discovery")
+			os.Exit(1)
+		}
+		go func() {
+			if err := provider.Sync(ctx, alloc); err != nil && ctx.Err() == nil {
+				log.Error(err, "file-based target discovery stopped unexpectedly")
+			}
+		}()
+	}
+
+	if *grpcListenAddr != "" {
+		grpcServer := server.NewGRPCServer(log, alloc)
+		go func() {
+			if err := grpcServer.Start(*grpcListenAddr); err != nil {
+				log.Error(err, "gRPC server stopped unexpectedly")
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			grpcServer.Shutdown()
+		}()
+	}
+
+	<-ctx.Done()
+}
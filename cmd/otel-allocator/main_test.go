@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCollectorWeight(t *testing.T) {
+	name, weight, err := parseCollectorWeight("collector-1=42.5")
+	require.NoError(t, err)
+	assert.Equal(t, "collector-1", name)
+	assert.Equal(t, 42.5, weight)
+}
+
+func TestParseCollectorWeightRejectsMissingEquals(t *testing.T) {
+	_, _, err := parseCollectorWeight("collector-1")
+	assert.Error(t, err)
+}
+
+func TestParseCollectorWeightRejectsEmptyName(t *testing.T) {
+	_, _, err := parseCollectorWeight("=42.5")
+	assert.Error(t, err)
+}
+
+func TestParseCollectorWeightRejectsNonNumericWeight(t *testing.T) {
+	_, _, err := parseCollectorWeight("collector-1=not-a-number")
+	assert.Error(t, err)
+}
@@ -0,0 +1,195 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime/debug"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/open-telemetry/opentelemetry-operator/cmd/otel-allocator/allocation"
+	"github.com/open-telemetry/opentelemetry-operator/cmd/otel-allocator/server/targetallocatorpb"
+)
+
+var activeStreams = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "otelcol_allocator_grpc_active_streams",
+	Help: "Number of collectors currently streaming target assignments over gRPC.",
+}, []string{"collector"})
+
+func init() {
+	prometheus.MustRegister(activeStreams)
+}
+
+// subscribable is the subset of Allocator that the gRPC server needs in order to watch a
+// collector's assignment. It is implemented by consistentHashingAllocator.
+type subscribable interface {
+	Subscribe(collectorName string) <-chan allocation.TargetDiff
+	Unsubscribe(collectorName string, ch <-chan allocation.TargetDiff)
+	TargetItems() map[string]*allocation.TargetItem
+}
+
+// GRPCServer implements the TargetAllocator gRPC service, streaming target assignments to
+// collectors as an alternative to polling the HTTP /jobs/{job}/targets endpoint.
+type GRPCServer struct {
+	targetallocatorpb.UnimplementedTargetAllocatorServer
+
+	log       logr.Logger
+	allocator subscribable
+	server    *grpc.Server
+}
+
+// NewGRPCServer builds a gRPC server exposing the TargetAllocator service, with panic recovery
+// and request logging interceptors already installed. The active-stream gauge is maintained by
+// WatchTargets itself.
+func NewGRPCServer(log logr.Logger, allocator subscribable) *GRPCServer {
+	s := &GRPCServer{
+		log:       log.WithName("grpc"),
+		allocator: allocator,
+	}
+	s.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.recoveryUnaryInterceptor, s.loggingUnaryInterceptor),
+		grpc.ChainStreamInterceptor(s.recoveryStreamInterceptor, s.loggingStreamInterceptor),
+	)
+	targetallocatorpb.RegisterTargetAllocatorServer(s.server, s)
+	return s
+}
+
+// Start listens on addr and serves the TargetAllocator service until the listener is closed or
+// Shutdown is called. It is meant to be run in its own goroutine.
+func (s *GRPCServer) Start(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.log.Info("starting gRPC target allocator server", "addr", addr)
+	return s.server.Serve(lis)
+}
+
+// Shutdown calls GracefulStop: it stops accepting new RPCs and blocks until in-flight RPCs have
+// returned. It does not close any subscriber channels, so an open WatchTargets stream keeps
+// Shutdown waiting until that stream's client goes away.
+func (s *GRPCServer) Shutdown() {
+	s.server.GracefulStop()
+}
+
+// WatchTargets streams the requesting collector's assignment: the current snapshot first, as a
+// single additions batch, followed by incremental diffs as the allocator reassigns targets.
+func (s *GRPCServer) WatchTargets(req *targetallocatorpb.CollectorID, stream targetallocatorpb.TargetAllocator_WatchTargetsServer) error {
+	name := req.GetName()
+	if name == "" {
+		return status.Error(codes.InvalidArgument, "collector name must not be empty")
+	}
+
+	diffs := s.allocator.Subscribe(name)
+	defer s.allocator.Unsubscribe(name, diffs)
+
+	activeStreams.WithLabelValues(name).Inc()
+	defer activeStreams.WithLabelValues(name).Dec()
+
+	initial := &targetallocatorpb.TargetAssignment{}
+	for _, item := range s.allocator.TargetItems() {
+		if item.CollectorName != name {
+			continue
+		}
+		initial.Additions = append(initial.Additions, toScrapeTarget(item))
+	}
+	if err := stream.Send(initial); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-diffs:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(&targetallocatorpb.TargetAssignment{
+				Additions: toScrapeTargets(d.Additions),
+				Removals:  toScrapeTargets(d.Removals),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toScrapeTargets(items []*allocation.TargetItem) []*targetallocatorpb.ScrapeTarget {
+	out := make([]*targetallocatorpb.ScrapeTarget, 0, len(items))
+	for _, item := range items {
+		out = append(out, toScrapeTarget(item))
+	}
+	return out
+}
+
+func toScrapeTarget(item *allocation.TargetItem) *targetallocatorpb.ScrapeTarget {
+	var url string
+	if len(item.TargetURL) > 0 {
+		url = item.TargetURL[0]
+	}
+	labels := make(map[string]string, len(item.Label))
+	for k, v := range item.Label {
+		labels[string(k)] = string(v)
+	}
+	return &targetallocatorpb.ScrapeTarget{
+		JobName:   item.JobName,
+		TargetUrl: url,
+		Labels:    labels,
+	}
+}
+
+// recoveryUnaryInterceptor converts a panic in a unary handler into a codes.Internal error and
+// logs the stack trace, rather than crashing the process.
+func (s *GRPCServer) recoveryUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Error(fmt.Errorf("%v", r), "panic in gRPC handler", "method", info.FullMethod, "stack", string(debug.Stack()))
+			err = status.Errorf(codes.Internal, "internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// loggingUnaryInterceptor logs every unary request at info level.
+func (s *GRPCServer) loggingUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	s.log.Info("gRPC request", "method", info.FullMethod)
+	return handler(ctx, req)
+}
+
+// recoveryStreamInterceptor is the streaming equivalent of recoveryUnaryInterceptor.
+func (s *GRPCServer) recoveryStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Error(fmt.Errorf("%v", r), "panic in gRPC stream handler", "method", info.FullMethod, "stack", string(debug.Stack()))
+			err = status.Errorf(codes.Internal, "internal error")
+		}
+	}()
+	return handler(srv, ss)
+}
+
+// loggingStreamInterceptor logs every streaming request at info level.
+func (s *GRPCServer) loggingStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	s.log.Info("gRPC stream started", "method", info.FullMethod)
+	return handler(srv, ss)
+}
@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/open-telemetry/opentelemetry-operator/cmd/otel-allocator/allocation"
+	"github.com/open-telemetry/opentelemetry-operator/cmd/otel-allocator/server/targetallocatorpb"
+)
+
+func TestToScrapeTarget(t *testing.T) {
+	item := &allocation.TargetItem{
+		JobName:   "example",
+		TargetURL: []string{"localhost:9090"},
+		Label:     model.LabelSet{"env": "prod"},
+	}
+
+	target := toScrapeTarget(item)
+
+	assert.Equal(t, "example", target.GetJobName())
+	assert.Equal(t, "localhost:9090", target.GetTargetUrl())
+	assert.Equal(t, "prod", target.GetLabels()["env"])
+}
+
+// fakeAllocator is a minimal subscribable used to exercise GRPCServer.WatchTargets without a
+// real consistentHashingAllocator.
+type fakeAllocator struct {
+	items map[string]*allocation.TargetItem
+	ch    chan allocation.TargetDiff
+}
+
+func (f *fakeAllocator) Subscribe(string) <-chan allocation.TargetDiff { return f.ch }
+func (f *fakeAllocator) Unsubscribe(string, <-chan allocation.TargetDiff) {
+	close(f.ch)
+}
+func (f *fakeAllocator) TargetItems() map[string]*allocation.TargetItem { return f.items }
+
+// TestWatchTargetsStreamsSnapshotThenDiff exercises WatchTargets end-to-end over a real gRPC
+// connection, proving the jsonCodec registered in targetallocatorpb actually round-trips on the
+// wire rather than just in-process.
+func TestWatchTargetsStreamsSnapshotThenDiff(t *testing.T) {
+	sdItem := &allocation.TargetItem{JobName: "example", TargetURL: []string{"localhost:9090"}, CollectorName: "collector-1"}
+	alloc := &fakeAllocator{
+		items: map[string]*allocation.TargetItem{sdItem.Hash(): sdItem},
+		ch:    make(chan allocation.TargetDiff, 1),
+	}
+
+	s := NewGRPCServer(logr.Discard(), alloc)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = s.server.Serve(lis) }()
+	defer s.Shutdown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := targetallocatorpb.NewTargetAllocatorClient(conn)
+	stream, err := client.WatchTargets(ctx, &targetallocatorpb.CollectorID{Name: "collector-1"}, targetallocatorpb.CallOption())
+	require.NoError(t, err)
+
+	initial, err := stream.Recv()
+	require.NoError(t, err)
+	require.Len(t, initial.GetAdditions(), 1)
+	assert.Equal(t, "example", initial.GetAdditions()[0].GetJobName())
+	assert.Equal(t, "localhost:9090", initial.GetAdditions()[0].GetTargetUrl())
+
+	newItem := &allocation.TargetItem{JobName: "new", TargetURL: []string{"localhost:9091"}, CollectorName: "collector-1"}
+	alloc.ch <- allocation.TargetDiff{Additions: []*allocation.TargetItem{newItem}}
+
+	diff, err := stream.Recv()
+	require.NoError(t, err)
+	require.Len(t, diff.GetAdditions(), 1)
+	assert.Equal(t, "new", diff.GetAdditions()[0].GetJobName())
+}
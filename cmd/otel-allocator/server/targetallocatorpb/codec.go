@@ -0,0 +1,58 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package targetallocatorpb
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// codecName is the content-subtype jsonCodec registers under. It must NOT be "proto": that's the
+// name grpc-go's built-in codec registers under and silently falls back to whenever a call
+// doesn't set a content-subtype, so reusing it would make importing this package hijack the
+// process-wide default codec for every other gRPC client/server sharing the binary.
+const codecName = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// CollectorID, ScrapeTarget and TargetAssignment are plain Go structs, not generated
+// google.golang.org/protobuf messages, so grpc-go's built-in "proto" codec can't marshal them:
+// it type-asserts every message to proto.Message and panics otherwise. jsonCodec registers a
+// json.Marshal/json.Unmarshal implementation under its own content-subtype name so callers opt in
+// explicitly via CallOption, rather than replacing the codec every other call in the process uses.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+// CallOption selects jsonCodec for a single call, without touching the process-wide default
+// codec. Every TargetAllocator client call must pass this, since the service's messages aren't
+// proto.Message and can't be marshaled by grpc-go's built-in codec.
+func CallOption() grpc.CallOption {
+	return grpc.CallContentSubtype(codecName)
+}
@@ -0,0 +1,63 @@
+// Package targetallocatorpb defines the wire types for the TargetAllocator gRPC service.
+//
+// These are hand-written rather than protoc-generated: they don't implement proto.Message, so
+// they are marshaled via the jsonCodec registered in codec.go instead of the default protobuf
+// wire format.
+package targetallocatorpb
+
+type CollectorID struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *CollectorID) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ScrapeTarget struct {
+	JobName   string            `protobuf:"bytes,1,opt,name=job_name,json=jobName,proto3" json:"job_name,omitempty"`
+	TargetUrl string            `protobuf:"bytes,2,opt,name=target_url,json=targetUrl,proto3" json:"target_url,omitempty"`
+	Labels    map[string]string `protobuf:"bytes,3,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *ScrapeTarget) GetJobName() string {
+	if x != nil {
+		return x.JobName
+	}
+	return ""
+}
+
+func (x *ScrapeTarget) GetTargetUrl() string {
+	if x != nil {
+		return x.TargetUrl
+	}
+	return ""
+}
+
+func (x *ScrapeTarget) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type TargetAssignment struct {
+	Additions []*ScrapeTarget `protobuf:"bytes,1,rep,name=additions,proto3" json:"additions,omitempty"`
+	Removals  []*ScrapeTarget `protobuf:"bytes,2,rep,name=removals,proto3" json:"removals,omitempty"`
+}
+
+func (x *TargetAssignment) GetAdditions() []*ScrapeTarget {
+	if x != nil {
+		return x.Additions
+	}
+	return nil
+}
+
+func (x *TargetAssignment) GetRemovals() []*ScrapeTarget {
+	if x != nil {
+		return x.Removals
+	}
+	return nil
+}
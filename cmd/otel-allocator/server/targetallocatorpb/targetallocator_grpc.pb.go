@@ -0,0 +1,114 @@
+// Client/server stubs for the TargetAllocator service, hand-written in the shape of
+// protoc-gen-go-grpc output so they're easy to swap for the real generated code later.
+
+package targetallocatorpb
+
+import (
+	context "context"
+
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// TargetAllocatorClient is the client API for the TargetAllocator service.
+type TargetAllocatorClient interface {
+	WatchTargets(ctx context.Context, in *CollectorID, opts ...grpc.CallOption) (TargetAllocator_WatchTargetsClient, error)
+}
+
+type targetAllocatorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewTargetAllocatorClient constructs a client for the TargetAllocator service.
+func NewTargetAllocatorClient(cc grpc.ClientConnInterface) TargetAllocatorClient {
+	return &targetAllocatorClient{cc}
+}
+
+func (c *targetAllocatorClient) WatchTargets(ctx context.Context, in *CollectorID, opts ...grpc.CallOption) (TargetAllocator_WatchTargetsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TargetAllocator_ServiceDesc.Streams[0], "/targetallocatorpb.TargetAllocator/WatchTargets", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &targetAllocatorWatchTargetsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TargetAllocator_WatchTargetsClient interface {
+	Recv() (*TargetAssignment, error)
+	grpc.ClientStream
+}
+
+type targetAllocatorWatchTargetsClient struct {
+	grpc.ClientStream
+}
+
+func (x *targetAllocatorWatchTargetsClient) Recv() (*TargetAssignment, error) {
+	m := new(TargetAssignment)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TargetAllocatorServer is the server API for the TargetAllocator service.
+// All implementations must embed UnimplementedTargetAllocatorServer for forward compatibility.
+type TargetAllocatorServer interface {
+	WatchTargets(*CollectorID, TargetAllocator_WatchTargetsServer) error
+	mustEmbedUnimplementedTargetAllocatorServer()
+}
+
+// UnimplementedTargetAllocatorServer must be embedded to have forward compatible implementations.
+type UnimplementedTargetAllocatorServer struct{}
+
+func (UnimplementedTargetAllocatorServer) WatchTargets(*CollectorID, TargetAllocator_WatchTargetsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTargets not implemented")
+}
+func (UnimplementedTargetAllocatorServer) mustEmbedUnimplementedTargetAllocatorServer() {}
+
+// RegisterTargetAllocatorServer registers impl with the grpc server s.
+func RegisterTargetAllocatorServer(s grpc.ServiceRegistrar, srv TargetAllocatorServer) {
+	s.RegisterService(&TargetAllocator_ServiceDesc, srv)
+}
+
+func _TargetAllocator_WatchTargets_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CollectorID)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TargetAllocatorServer).WatchTargets(m, &targetAllocatorWatchTargetsServer{stream})
+}
+
+type TargetAllocator_WatchTargetsServer interface {
+	Send(*TargetAssignment) error
+	grpc.ServerStream
+}
+
+type targetAllocatorWatchTargetsServer struct {
+	grpc.ServerStream
+}
+
+func (x *targetAllocatorWatchTargetsServer) Send(m *TargetAssignment) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// TargetAllocator_ServiceDesc is the grpc.ServiceDesc for TargetAllocator service.
+var TargetAllocator_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "targetallocatorpb.TargetAllocator",
+	HandlerType: (*TargetAllocatorServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTargets",
+			Handler:       _TargetAllocator_WatchTargets_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "targetallocator.proto",
+}
@@ -0,0 +1,329 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapters_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/open-telemetry/opentelemetry-operator/pkg/collector/adapters"
+	"github.com/open-telemetry/opentelemetry-operator/pkg/collector/parser"
+)
+
+func TestExtractPortsFromExportersConfig(t *testing.T) {
+	configStr := `receivers:
+  examplereceiver:
+    endpoint: "0.0.0.0:12345"
+exporters:
+  prometheus:
+    endpoint: "0.0.0.0:8888"
+  otlphttp:
+    endpoint: "0.0.0.0:4319"
+  logging:
+service:
+  pipelines:
+    metrics:
+      receivers: [examplereceiver]
+      exporters: [prometheus, otlphttp, logging]
+`
+
+	config, err := adapters.ConfigFromString(configStr)
+	require.NoError(t, err)
+
+	ports, err := adapters.ConfigToExporterPorts(logger, config)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, p := range ports {
+		names = append(names, p.Name)
+	}
+	// otlphttp dials out to a remote collector and doesn't get a Service port.
+	assert.ElementsMatch(t, []string{"prometheus"}, names)
+}
+
+// TestExporterParserFailureIsSkippedNotPropagated matches ConfigToReceiverPorts' behavior
+// (TestParserFailed): one exporter's parser erroring shouldn't fail the whole call and drop every
+// other exporter's ports along with it.
+func TestExporterParserFailureIsSkippedNotPropagated(t *testing.T) {
+	parser.RegisterExporter("mock-exporter", func(logr.Logger, string, map[interface{}]interface{}) parser.ExporterParser {
+		return &mockExporterParser{}
+	})
+
+	configStr := `receivers:
+  examplereceiver:
+    endpoint: "0.0.0.0:12345"
+exporters:
+  mock-exporter:
+  prometheus:
+    endpoint: "0.0.0.0:8888"
+service:
+  pipelines:
+    metrics:
+      receivers: [examplereceiver]
+      exporters: [mock-exporter, prometheus]
+`
+
+	config, err := adapters.ConfigFromString(configStr)
+	require.NoError(t, err)
+
+	ports, err := adapters.ConfigToExporterPorts(logger, config)
+	require.NoError(t, err)
+
+	var names []string
+	for _, p := range ports {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"prometheus"}, names)
+}
+
+type mockExporterParser struct{}
+
+func (m *mockExporterParser) Ports() ([]corev1.ServicePort, error) {
+	return nil, errors.New("mocked exporter parse error")
+}
+
+func (m *mockExporterParser) ParserName() string { return "__mock-exporter" }
+
+func TestExtractPortsFromExtensionsConfig(t *testing.T) {
+	configStr := `receivers:
+  examplereceiver:
+    endpoint: "0.0.0.0:12345"
+extensions:
+  health_check:
+    endpoint: "0.0.0.0:13133"
+  pprof:
+    endpoint: "0.0.0.0:1777"
+service:
+  extensions: [health_check, pprof]
+  pipelines:
+    metrics:
+      receivers: [examplereceiver]
+      exporters: [logging]
+`
+
+	config, err := adapters.ConfigFromString(configStr)
+	require.NoError(t, err)
+
+	ports, err := adapters.ConfigToExtensionPorts(logger, config)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, p := range ports {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"health_check", "pprof"}, names)
+}
+
+// TestExtensionParserFailureIsSkippedNotPropagated is the extension-side equivalent of
+// TestExporterParserFailureIsSkippedNotPropagated.
+func TestExtensionParserFailureIsSkippedNotPropagated(t *testing.T) {
+	parser.RegisterExtension("mock-extension", func(logr.Logger, string, map[interface{}]interface{}) parser.ExtensionParser {
+		return &mockExtensionParser{}
+	})
+
+	configStr := `receivers:
+  examplereceiver:
+    endpoint: "0.0.0.0:12345"
+extensions:
+  mock-extension:
+  health_check:
+    endpoint: "0.0.0.0:13133"
+service:
+  extensions: [mock-extension, health_check]
+  pipelines:
+    metrics:
+      receivers: [examplereceiver]
+      exporters: [logging]
+`
+
+	config, err := adapters.ConfigFromString(configStr)
+	require.NoError(t, err)
+
+	ports, err := adapters.ConfigToExtensionPorts(logger, config)
+	require.NoError(t, err)
+
+	var names []string
+	for _, p := range ports {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"health_check"}, names)
+}
+
+type mockExtensionParser struct{}
+
+func (m *mockExtensionParser) Ports() ([]corev1.ServicePort, error) {
+	return nil, errors.New("mocked extension parse error")
+}
+
+func (m *mockExtensionParser) ParserName() string { return "__mock-extension" }
+
+func TestExtensionsNotStartedWithoutServiceList(t *testing.T) {
+	configStr := `receivers:
+  examplereceiver:
+    endpoint: "0.0.0.0:12345"
+extensions:
+  health_check:
+    endpoint: "0.0.0.0:13133"
+service:
+  pipelines:
+    metrics:
+      receivers: [examplereceiver]
+      exporters: [logging]
+`
+
+	config, err := adapters.ConfigFromString(configStr)
+	require.NoError(t, err)
+
+	ports, err := adapters.ConfigToExtensionPorts(logger, config)
+	assert.NoError(t, err)
+	assert.Empty(t, ports)
+}
+
+func TestServicePortsForCollectorConfigDefaultsToReceiversOnly(t *testing.T) {
+	configStr := `receivers:
+  examplereceiver:
+    endpoint: "0.0.0.0:12345"
+exporters:
+  prometheus:
+    endpoint: "0.0.0.0:8888"
+extensions:
+  health_check:
+    endpoint: "0.0.0.0:13133"
+service:
+  extensions: [health_check]
+  pipelines:
+    metrics:
+      receivers: [examplereceiver]
+      exporters: [prometheus]
+`
+
+	config, err := adapters.ConfigFromString(configStr)
+	require.NoError(t, err)
+
+	ports, err := adapters.ServicePortsForCollectorConfig(logger, config, false)
+	require.NoError(t, err)
+
+	var names []string
+	for _, p := range ports {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"examplereceiver"}, names)
+}
+
+func TestServicePortsForCollectorConfigOptedIntoExporterExtensionPorts(t *testing.T) {
+	configStr := `receivers:
+  examplereceiver:
+    endpoint: "0.0.0.0:12345"
+exporters:
+  prometheus:
+    endpoint: "0.0.0.0:8888"
+extensions:
+  health_check:
+    endpoint: "0.0.0.0:13133"
+service:
+  extensions: [health_check]
+  pipelines:
+    metrics:
+      receivers: [examplereceiver]
+      exporters: [prometheus]
+`
+
+	config, err := adapters.ConfigFromString(configStr)
+	require.NoError(t, err)
+
+	ports, err := adapters.ServicePortsForCollectorConfig(logger, config, true)
+	require.NoError(t, err)
+
+	var names []string
+	for _, p := range ports {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"examplereceiver", "prometheus", "health_check"}, names)
+}
+
+func TestNoExportersOrExtensions(t *testing.T) {
+	configStr := `receivers:
+  examplereceiver:
+    endpoint: "0.0.0.0:12345"
+service:
+  pipelines:
+    metrics:
+      receivers: [examplereceiver]
+      exporters: [logging]
+`
+
+	config, err := adapters.ConfigFromString(configStr)
+	require.NoError(t, err)
+
+	_, err = adapters.ConfigToExporterPorts(logger, config)
+	assert.Equal(t, adapters.ErrNoExporters, err)
+
+	ports, err := adapters.ConfigToExtensionPorts(logger, config)
+	assert.NoError(t, err)
+	assert.Empty(t, ports)
+}
+
+func TestServiceForCollectorConfig(t *testing.T) {
+	configStr := `receivers:
+  examplereceiver:
+    endpoint: "0.0.0.0:12345"
+exporters:
+  prometheus:
+    endpoint: "0.0.0.0:8888"
+service:
+  pipelines:
+    metrics:
+      receivers: [examplereceiver]
+      exporters: [prometheus]
+`
+
+	config, err := adapters.ConfigFromString(configStr)
+	require.NoError(t, err)
+
+	labels := map[string]string{"app.kubernetes.io/managed-by": "opentelemetry-operator"}
+	selector := map[string]string{"app.kubernetes.io/instance": "example-collector"}
+
+	svc, err := adapters.ServiceForCollectorConfig(logger, "example-collector", "default", labels, selector, config, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example-collector", svc.Name)
+	assert.Equal(t, "default", svc.Namespace)
+	assert.Equal(t, labels, svc.Labels)
+	assert.Equal(t, selector, svc.Spec.Selector)
+
+	var names []string
+	for _, p := range svc.Spec.Ports {
+		names = append(names, p.Name)
+	}
+	assert.ElementsMatch(t, []string{"examplereceiver"}, names)
+}
+
+func TestServiceForCollectorConfigPropagatesPortError(t *testing.T) {
+	configStr := `exporters:
+  prometheus:
+    endpoint: "0.0.0.0:8888"
+`
+
+	config, err := adapters.ConfigFromString(configStr)
+	require.NoError(t, err)
+
+	_, err = adapters.ServiceForCollectorConfig(logger, "example-collector", "default", nil, nil, config, false)
+	assert.Equal(t, adapters.ErrNoReceivers, err)
+}
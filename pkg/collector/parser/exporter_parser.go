@@ -0,0 +1,147 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ExporterParser translates an exporter's YAML configuration block into the corev1.ServicePort
+// entries it requires, mirroring ReceiverParser's role for receivers.
+type ExporterParser interface {
+	// Ports returns the service ports to be used by this particular exporter.
+	Ports() ([]corev1.ServicePort, error)
+
+	// ParserName returns the name of this parser.
+	ParserName() string
+}
+
+// ExporterParserBuilder builds an ExporterParser for a named exporter instance.
+type ExporterParserBuilder func(logger logr.Logger, name string, config map[interface{}]interface{}) ExporterParser
+
+var exporterParserRegistry = make(map[string]ExporterParserBuilder)
+
+// RegisterExporter adds a new parser builder to the list of known exporter parsers, keyed by the
+// exporter's type (the part of its name before an optional "/name" suffix).
+func RegisterExporter(name string, builder ExporterParserBuilder) {
+	exporterParserRegistry[name] = builder
+}
+
+// ExporterFor returns a parser for the given exporter instance name, falling back to a generic
+// endpoint-based parser when no type-specific parser has been registered.
+func ExporterFor(logger logr.Logger, name string, config map[interface{}]interface{}) ExporterParser {
+	builder, ok := exporterParserRegistry[exporterType(name)]
+	if !ok {
+		builder = NewGenericExporterParser
+	}
+	return builder(logger, name, config)
+}
+
+// exporterType returns the exporter type from an instance name such as "otlp/2", stripping the
+// optional "/name" disambiguator.
+func exporterType(name string) string {
+	if i := strings.Index(name, "/"); i != -1 {
+		return name[:i]
+	}
+	return name
+}
+
+type genericExporterParser struct {
+	logger logr.Logger
+	name   string
+	config map[interface{}]interface{}
+}
+
+// NewGenericExporterParser returns an ExporterParser that exposes a single port taken from the
+// exporter's "endpoint" field, if present. It is used for exporters without a dedicated parser.
+func NewGenericExporterParser(logger logr.Logger, name string, config map[interface{}]interface{}) ExporterParser {
+	return &genericExporterParser{logger: logger, name: name, config: config}
+}
+
+func (g *genericExporterParser) Ports() ([]corev1.ServicePort, error) {
+	endpoint, ok := g.config["endpoint"]
+	if !ok {
+		return nil, nil
+	}
+	str, ok := endpoint.(string)
+	if !ok {
+		return nil, nil
+	}
+	port, err := portFromEndpoint(str)
+	if err != nil {
+		g.logger.V(2).Info("couldn't parse the endpoint's port", "exporter", g.name, "endpoint", str)
+		return nil, nil
+	}
+	return []corev1.ServicePort{{
+		Name: sanitizePortName(g.name),
+		Port: port,
+	}}, nil
+}
+
+func (g *genericExporterParser) ParserName() string {
+	return "__" + g.name
+}
+
+// noopExporterParser is used for exporters whose "endpoint" field names a remote address to push
+// data to, rather than a local port to accept connections on. Treating their endpoint as a
+// listening port, like the generic parser does, would expose a Service port nothing is listening
+// on.
+type noopExporterParser struct {
+	name string
+}
+
+// NewNoopExporterParser returns an ExporterParser that never exposes a port. It is used for
+// dial-out exporters such as otlp and otlphttp.
+func NewNoopExporterParser(_ logr.Logger, name string, _ map[interface{}]interface{}) ExporterParser {
+	return &noopExporterParser{name: name}
+}
+
+func (n *noopExporterParser) Ports() ([]corev1.ServicePort, error) {
+	return nil, nil
+}
+
+func (n *noopExporterParser) ParserName() string {
+	return "__" + n.name
+}
+
+// portFromEndpoint extracts the numeric port from a "host:port" style endpoint string.
+func portFromEndpoint(endpoint string) (int32, error) {
+	i := strings.LastIndex(endpoint, ":")
+	if i == -1 {
+		return 0, strconv.ErrSyntax
+	}
+	port, err := strconv.Atoi(endpoint[i+1:])
+	if err != nil {
+		return 0, err
+	}
+	return int32(port), nil
+}
+
+// sanitizePortName converts an instance name like "jaeger/custom" into a valid Service port name.
+func sanitizePortName(name string) string {
+	return strings.ReplaceAll(name, "/", "-")
+}
+
+func init() {
+	RegisterExporter("prometheus", NewGenericExporterParser)
+	// otlp and otlphttp dial out to a remote collector; their "endpoint" isn't a port this
+	// collector listens on, so they get no Service port.
+	RegisterExporter("otlp", NewNoopExporterParser)
+	RegisterExporter("otlphttp", NewNoopExporterParser)
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ExtensionParser translates an extension's YAML configuration block into the corev1.ServicePort
+// entries it requires, mirroring ReceiverParser's role for receivers.
+type ExtensionParser interface {
+	// Ports returns the service ports to be used by this particular extension.
+	Ports() ([]corev1.ServicePort, error)
+
+	// ParserName returns the name of this parser.
+	ParserName() string
+}
+
+// ExtensionParserBuilder builds an ExtensionParser for a named extension instance.
+type ExtensionParserBuilder func(logger logr.Logger, name string, config map[interface{}]interface{}) ExtensionParser
+
+var extensionParserRegistry = make(map[string]ExtensionParserBuilder)
+
+// RegisterExtension adds a new parser builder to the list of known extension parsers, keyed by
+// the extension's type (the part of its name before an optional "/name" suffix).
+func RegisterExtension(name string, builder ExtensionParserBuilder) {
+	extensionParserRegistry[name] = builder
+}
+
+// ExtensionFor returns a parser for the given extension instance name, falling back to a generic
+// endpoint-based parser when no type-specific parser has been registered.
+func ExtensionFor(logger logr.Logger, name string, config map[interface{}]interface{}) ExtensionParser {
+	builder, ok := extensionParserRegistry[exporterType(name)]
+	if !ok {
+		builder = NewGenericExtensionParser
+	}
+	return builder(logger, name, config)
+}
+
+type genericExtensionParser struct {
+	logger logr.Logger
+	name   string
+	config map[interface{}]interface{}
+}
+
+// NewGenericExtensionParser returns an ExtensionParser that exposes a single port taken from the
+// extension's "endpoint" field, if present. It is used for extensions without a dedicated parser.
+func NewGenericExtensionParser(logger logr.Logger, name string, config map[interface{}]interface{}) ExtensionParser {
+	return &genericExtensionParser{logger: logger, name: name, config: config}
+}
+
+func (g *genericExtensionParser) Ports() ([]corev1.ServicePort, error) {
+	endpoint, ok := g.config["endpoint"]
+	if !ok {
+		return nil, nil
+	}
+	str, ok := endpoint.(string)
+	if !ok {
+		return nil, nil
+	}
+	port, err := portFromEndpoint(str)
+	if err != nil {
+		g.logger.V(2).Info("couldn't parse the endpoint's port", "extension", g.name, "endpoint", str)
+		return nil, nil
+	}
+	return []corev1.ServicePort{{
+		Name: sanitizePortName(g.name),
+		Port: port,
+	}}, nil
+}
+
+func (g *genericExtensionParser) ParserName() string {
+	return "__" + g.name
+}
+
+func init() {
+	RegisterExtension("health_check", NewGenericExtensionParser)
+	RegisterExtension("pprof", NewGenericExtensionParser)
+	RegisterExtension("zpages", NewGenericExtensionParser)
+	RegisterExtension("jaeger_query", NewGenericExtensionParser)
+}